@@ -0,0 +1,76 @@
+// Command tfsnow-reverse connects to a Snowflake account and emits
+// ready-to-apply snowflake_table resource blocks, plus a shell script of
+// matching `terraform import` commands, for the tables that already exist in
+// a chosen database/schema. This unblocks adoption on existing warehouses
+// without hand-writing resources for every table.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/chanzuckerberg/terraform-provider-snowflake/pkg/snowflake/reverse"
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+func main() {
+	var (
+		account     = flag.String("account", os.Getenv("SNOWFLAKE_ACCOUNT"), "Snowflake account identifier")
+		user        = flag.String("user", os.Getenv("SNOWFLAKE_USER"), "Snowflake user")
+		password    = flag.String("password", os.Getenv("SNOWFLAKE_PASSWORD"), "Snowflake password")
+		database    = flag.String("database", "", "database to reverse engineer (required)")
+		schemaName  = flag.String("schema", "", "schema to reverse engineer (default: every schema in the database)")
+		include     = flag.String("include", "", "only emit tables/views whose name matches this regexp")
+		exclude     = flag.String("exclude", "", "skip tables/views whose name matches this regexp")
+		withViews   = flag.Bool("include-views", false, "emit snowflake_view blocks (not supported: setting it makes the command fail)")
+		withSchemas = flag.Bool("include-schemas", false, "emit snowflake_schema blocks (not supported: setting it makes the command fail)")
+		outDir      = flag.String("out-dir", "", "write one .tf/.sh file per schema to this directory")
+		toStdout    = flag.Bool("stdout", false, "write generated HCL to stdout instead of --out-dir")
+	)
+	flag.Parse()
+
+	if *database == "" {
+		log.Fatal("-database is required")
+	}
+
+	if *outDir == "" && !*toStdout {
+		log.Fatal("one of -out-dir or -stdout is required")
+	}
+
+	opts, err := reverse.NewOptions(*database, *schemaName, *include, *exclude, *withViews, *withSchemas)
+	if err != nil {
+		log.Fatalf("invalid options: %v", err)
+	}
+
+	db, err := sql.Open("snowflake", fmt.Sprintf("%v:%v@%v", *user, *password, *account))
+	if err != nil {
+		log.Fatalf("unable to connect to snowflake: %v", err)
+	}
+	defer db.Close()
+
+	files, err := reverse.Generate(db, opts)
+	if err != nil {
+		log.Fatalf("unable to reverse engineer %v: %v", *database, err)
+	}
+
+	if *toStdout {
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("# --- %v ---\n%v\n", name, files[name])
+		}
+		return
+	}
+
+	if err := reverse.WriteFiles(*outDir, files); err != nil {
+		log.Fatalf("unable to write output files to %v: %v", *outDir, err)
+	}
+}
@@ -0,0 +1,73 @@
+package datasources
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/chanzuckerberg/terraform-provider-snowflake/pkg/snowflake"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+var migrationStatusSchema = map[string]*schema.Schema{
+	"database": &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		Description: "The database holding the migrations table.",
+	},
+	"schema": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "PUBLIC",
+		Description: "The schema holding the migrations table.",
+	},
+	"migrations_table": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "schema_migrations",
+		Description: "Name of the migrations table to report on.",
+	},
+	"version": &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The current migration version.",
+	},
+	"dirty": &schema.Schema{
+		Type:        schema.TypeBool,
+		Computed:    true,
+		Description: "Whether the most recent migration failed partway through and needs to be fixed with force_version.",
+	},
+}
+
+// MigrationStatus returns a pointer to the data source reporting on the
+// current version and dirty state of a snowflake_migration resource's
+// migrations table.
+func MigrationStatus() *schema.Resource {
+	return &schema.Resource{
+		Read:   ReadMigrationStatus,
+		Schema: migrationStatusSchema,
+	}
+}
+
+// ReadMigrationStatus implements schema.ReadFunc
+func ReadMigrationStatus(data *schema.ResourceData, meta interface{}) error {
+	db := meta.(*sql.DB)
+	database := data.Get("database").(string)
+	schemaName := data.Get("schema").(string)
+	table := data.Get("migrations_table").(string)
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	row := snowflake.QueryRow(db, builder.CurrentVersion())
+	v, err := snowflake.ScanMigrationVersion(row)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%v|%v|%v", database, schemaName, table))
+
+	if err := data.Set("version", v.Version.Int64); err != nil {
+		return err
+	}
+
+	return data.Set("dirty", v.Dirty.Bool)
+}
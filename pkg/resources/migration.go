@@ -0,0 +1,503 @@
+package resources
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanzuckerberg/terraform-provider-snowflake/pkg/snowflake"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// migrationFilePattern matches golang-migrate style numbered migration
+// files, e.g. "0001_create_widgets.up.sql" / "0001_create_widgets.down.sql".
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+
+// migrationStep is a single numbered migration, with its up and (optional)
+// down SQL, gathered from either the "source" directory or an inline
+// "statements" block.
+type migrationStep struct {
+	version int
+	up      string
+	down    string
+}
+
+var migrationSchema = map[string]*schema.Schema{
+	"database": &schema.Schema{
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The database holding the migrations table.",
+	},
+	"schema": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "PUBLIC",
+		ForceNew:    true,
+		Description: "The schema holding the migrations table.",
+	},
+	"migrations_table": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Default:     "schema_migrations",
+		ForceNew:    true,
+		Description: "Name of the table used to track which migrations have been applied.",
+	},
+	"source": &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Directory of numbered up/down SQL files, e.g. 0001_create_widgets.up.sql, to apply in addition to any inline statements blocks.",
+	},
+	"statements": &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Inline migration steps, applied in version order alongside any files under source.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"version": &schema.Schema{
+					Type:        schema.TypeInt,
+					Required:    true,
+					Description: "Monotonically increasing version number for this migration step.",
+				},
+				"up": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "SQL statement(s) to apply when migrating up to this version.",
+				},
+				"down": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "SQL statement(s) to apply when migrating down from this version.",
+				},
+			},
+		},
+	},
+	"force_version": &schema.Schema{
+		Type:        schema.TypeInt,
+		Optional:    true,
+		Description: "Forces the recorded version without running any SQL. Used to recover a migrations table left dirty by a failed apply.",
+	},
+	"floor_version": &schema.Schema{
+		Type:        schema.TypeInt,
+		Optional:    true,
+		Default:     0,
+		Description: "On delete, down migrations are applied until the recorded version reaches this floor.",
+	},
+	"version": &schema.Schema{
+		Type:        schema.TypeInt,
+		Computed:    true,
+		Description: "The current migration version after the last apply.",
+	},
+}
+
+// Migration returns a pointer to the resource representing a set of
+// versioned SQL migrations.
+func Migration() *schema.Resource {
+	return &schema.Resource{
+		Create: CreateMigration,
+		Read:   ReadMigration,
+		Update: UpdateMigration,
+		Delete: DeleteMigration,
+		Exists: MigrationExists,
+
+		Schema: migrationSchema,
+	}
+}
+
+// loadMigrationSteps gathers migration steps from the "source" directory and
+// the inline "statements" blocks, and returns them sorted by version. It is
+// an error for two steps to share a version.
+func loadMigrationSteps(data *schema.ResourceData) ([]migrationStep, error) {
+	byVersion := map[int]migrationStep{}
+
+	if dir, ok := data.GetOk("source"); ok {
+		steps, err := loadMigrationFiles(dir.(string))
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range steps {
+			byVersion[s.version] = s
+		}
+	}
+
+	for _, raw := range data.Get("statements").([]interface{}) {
+		s := raw.(map[string]interface{})
+		version := s["version"].(int)
+		if _, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %v between source and statements", version)
+		}
+		byVersion[version] = migrationStep{
+			version: version,
+			up:      s["up"].(string),
+			down:    s["down"].(string),
+		}
+	}
+
+	steps := make([]migrationStep, 0, len(byVersion))
+	for _, s := range byVersion {
+		steps = append(steps, s)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].version < steps[j].version })
+
+	return steps, nil
+}
+
+// loadMigrationFiles reads up/down SQL files named like "0001_foo.up.sql" /
+// "0001_foo.down.sql" out of dir and pairs them up by version.
+func loadMigrationFiles(dir string) ([]migrationStep, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading migration source directory %v", dir)
+	}
+
+	byVersion := map[int]migrationStep{}
+	for _, e := range entries {
+		m := migrationFilePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing migration version from %v", e.Name())
+		}
+
+		contents, err := ioutil.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading migration file %v", e.Name())
+		}
+
+		step := byVersion[version]
+		step.version = version
+		if m[2] == "up" {
+			step.up = string(contents)
+		} else {
+			step.down = string(contents)
+		}
+		byVersion[version] = step
+	}
+
+	steps := make([]migrationStep, 0, len(byVersion))
+	for _, s := range byVersion {
+		steps = append(steps, s)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].version < steps[j].version })
+
+	return steps, nil
+}
+
+// currentMigrationVersion reads the current version and dirty bit from the
+// migrations table, returning version 0 and dirty false if no rows exist yet.
+func currentMigrationVersion(db *sql.DB, builder *snowflake.MigrationBuilder) (int, bool, error) {
+	row := snowflake.QueryRow(db, builder.CurrentVersion())
+	v, err := snowflake.ScanMigrationVersion(row)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return int(v.Version.Int64), v.Dirty.Bool, nil
+}
+
+// applyMigrationUp applies a single step's up SQL inside a transaction and
+// records the resulting version, marking it dirty until the statement and
+// the bookkeeping update both succeed. UpsertVersion is called twice for the
+// same version (dirty, then clean) but always leaves exactly one row behind.
+func applyMigrationUp(db *sql.DB, builder *snowflake.MigrationBuilder, step migrationStep) error {
+	if err := snowflake.Exec(db, builder.UpsertVersion(step.version, true)); err != nil {
+		return errors.Wrapf(err, "marking migration %v dirty", step.version)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrapf(err, "starting transaction for migration %v", step.version)
+	}
+
+	if _, err := tx.Exec(step.up); err != nil {
+		tx.Rollback()
+		return errors.Wrapf(err, "applying migration %v", step.version)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrapf(err, "committing migration %v", step.version)
+	}
+
+	if err := snowflake.Exec(db, builder.UpsertVersion(step.version, false)); err != nil {
+		return errors.Wrapf(err, "marking migration %v clean", step.version)
+	}
+
+	return nil
+}
+
+// applyMigrationDown runs a single step's down SQL and removes its
+// bookkeeping row, so the previous version becomes current again.
+func applyMigrationDown(db *sql.DB, builder *snowflake.MigrationBuilder, step migrationStep) error {
+	if step.down == "" {
+		return fmt.Errorf("migration %v has no down statement to roll back with", step.version)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return errors.Wrapf(err, "starting transaction for migration %v rollback", step.version)
+	}
+
+	if _, err := tx.Exec(step.down); err != nil {
+		tx.Rollback()
+		return errors.Wrapf(err, "rolling back migration %v", step.version)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrapf(err, "committing rollback of migration %v", step.version)
+	}
+
+	return snowflake.Exec(db, builder.DeleteVersion(step.version))
+}
+
+// CreateMigration implements schema.CreateFunc
+func CreateMigration(data *schema.ResourceData, meta interface{}) error {
+	db := meta.(*sql.DB)
+	database := data.Get("database").(string)
+	schemaName := data.Get("schema").(string)
+	table := data.Get("migrations_table").(string)
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	if err := snowflake.Exec(db, builder.Create()); err != nil {
+		return errors.Wrapf(err, "error creating migrations table %v", table)
+	}
+
+	if err := snowflake.Exec(db, builder.EnsureLockRow()); err != nil {
+		return errors.Wrapf(err, "error creating advisory lock row for migrations table %v", table)
+	}
+
+	if err := applyPendingMigrations(data, meta, builder); err != nil {
+		return err
+	}
+
+	data.SetId(fmt.Sprintf("%v|%v|%v", database, schemaName, table))
+
+	return ReadMigration(data, meta)
+}
+
+// acquireMigrationLock takes the advisory lock row so that two concurrent
+// applies against the same migrations table can't both read the same
+// current version and race each other's dirty-bit bookkeeping. It is not a
+// true distributed lock (no blocking/wait queue), just a fail-fast guard, since
+// Snowflake has no SELECT ... FOR UPDATE to block on.
+func acquireMigrationLock(db *sql.DB, builder *snowflake.MigrationBuilder) error {
+	res, err := db.Exec(builder.AcquireLock())
+	if err != nil {
+		return errors.Wrap(err, "error acquiring migration lock")
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "error acquiring migration lock")
+	}
+	if n == 0 {
+		return fmt.Errorf("migrations table is locked by another apply; if this is stale, clear the dirty bit on the version = 0 lock row to recover")
+	}
+
+	return nil
+}
+
+// releaseMigrationLock frees the advisory lock row taken by
+// acquireMigrationLock.
+func releaseMigrationLock(db *sql.DB, builder *snowflake.MigrationBuilder) error {
+	return errors.Wrap(snowflake.Exec(db, builder.ReleaseLock()), "error releasing migration lock")
+}
+
+// applyPendingMigrations brings the migrations table from its current
+// version up to force_version (if set) or the highest known version,
+// honoring force_version as a direct, SQL-less pointer update for recovering
+// from a dirty table. It holds the advisory lock row for the duration, so a
+// concurrent apply against the same table fails closed instead of racing.
+func applyPendingMigrations(data *schema.ResourceData, meta interface{}, builder *snowflake.MigrationBuilder) error {
+	db := meta.(*sql.DB)
+
+	if err := acquireMigrationLock(db, builder); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(db, builder)
+
+	current, dirty, err := currentMigrationVersion(db, builder)
+	if err != nil {
+		return errors.Wrap(err, "error reading current migration version")
+	}
+
+	if v, ok := data.GetOk("force_version"); ok {
+		forced := v.(int)
+		// Rows above the forced version would otherwise still be reported by
+		// CurrentVersion, so remove them before recording the forced one.
+		for current > forced {
+			if err := snowflake.Exec(db, builder.DeleteVersion(current)); err != nil {
+				return errors.Wrapf(err, "error forcing migration version to %v", forced)
+			}
+			if current, _, err = currentMigrationVersion(db, builder); err != nil {
+				return errors.Wrapf(err, "error forcing migration version to %v", forced)
+			}
+		}
+		if err := snowflake.Exec(db, builder.UpsertVersion(forced, false)); err != nil {
+			return errors.Wrapf(err, "error forcing migration version to %v", forced)
+		}
+		return nil
+	}
+
+	if dirty {
+		return fmt.Errorf("migrations table is marked dirty at version %v; fix the underlying schema and set force_version to recover", current)
+	}
+
+	steps, err := loadMigrationSteps(data)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range steps {
+		if step.version <= current {
+			continue
+		}
+		if err := applyMigrationUp(db, builder, step); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReadMigration implements schema.ReadFunc
+func ReadMigration(data *schema.ResourceData, meta interface{}) error {
+	db := meta.(*sql.DB)
+	database, schemaName, table, err := splitMigrationID(data.Id())
+	if err != nil {
+		return err
+	}
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	current, _, err := currentMigrationVersion(db, builder)
+	if err != nil {
+		return errors.Wrap(err, "error reading current migration version")
+	}
+
+	if err := data.Set("database", database); err != nil {
+		return err
+	}
+	if err := data.Set("schema", schemaName); err != nil {
+		return err
+	}
+	if err := data.Set("migrations_table", table); err != nil {
+		return err
+	}
+
+	return data.Set("version", current)
+}
+
+// UpdateMigration implements schema.UpdateFunc
+func UpdateMigration(data *schema.ResourceData, meta interface{}) error {
+	data.Partial(true)
+
+	database, schemaName, table, err := splitMigrationID(data.Id())
+	if err != nil {
+		return err
+	}
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	if err := applyPendingMigrations(data, meta, builder); err != nil {
+		return err
+	}
+
+	data.SetPartial("source")
+	data.SetPartial("statements")
+	data.SetPartial("force_version")
+
+	return ReadMigration(data, meta)
+}
+
+// DeleteMigration implements schema.DeleteFunc
+func DeleteMigration(data *schema.ResourceData, meta interface{}) error {
+	db := meta.(*sql.DB)
+	database, schemaName, table, err := splitMigrationID(data.Id())
+	if err != nil {
+		return err
+	}
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	if err := acquireMigrationLock(db, builder); err != nil {
+		return err
+	}
+	defer releaseMigrationLock(db, builder)
+
+	steps, err := loadMigrationSteps(data)
+	if err != nil {
+		return err
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].version > steps[j].version })
+
+	current, dirty, err := currentMigrationVersion(db, builder)
+	if err != nil {
+		return errors.Wrap(err, "error reading current migration version")
+	}
+	if dirty {
+		return fmt.Errorf("migrations table is marked dirty at version %v; fix the underlying schema and set force_version to recover before destroying", current)
+	}
+
+	floor := data.Get("floor_version").(int)
+
+	for _, step := range steps {
+		if step.version > current || step.version <= floor {
+			continue
+		}
+		if err := applyMigrationDown(db, builder, step); err != nil {
+			return errors.Wrapf(err, "error deleting migration resource %v", data.Id())
+		}
+		current = step.version - 1
+	}
+
+	data.SetId("")
+
+	return nil
+}
+
+// MigrationExists implements schema.ExistsFunc
+func MigrationExists(data *schema.ResourceData, meta interface{}) (bool, error) {
+	db := meta.(*sql.DB)
+	database, schemaName, table, err := splitMigrationID(data.Id())
+	if err != nil {
+		return false, err
+	}
+
+	builder := snowflake.Migration(table).WithDB(database).WithSchema(schemaName)
+
+	rows, err := db.Query(builder.Show())
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// splitMigrationID takes the <database>|<schema>|<migrations_table> ID and
+// returns the database, schema and migrations table name.
+func splitMigrationID(v string) (string, string, string, error) {
+	arr := strings.Split(v, "|")
+	if len(arr) != 3 {
+		return "", "", "", fmt.Errorf("ID %v is invalid", v)
+	}
+
+	return arr[0], arr[1], arr[2], nil
+}
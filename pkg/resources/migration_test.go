@@ -0,0 +1,212 @@
+package resources
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chanzuckerberg/terraform-provider-snowflake/pkg/snowflake"
+)
+
+// fakeMigrationsStore is an in-memory stand-in for a real migrations table:
+// just enough of Snowflake's row semantics (MERGE upserts a row in place,
+// DELETE removes one) to drive applyMigrationUp and currentMigrationVersion
+// through a fake driver and assert on the exact row(s) left behind.
+type fakeMigrationsStore struct {
+	mu   sync.Mutex
+	rows map[int]bool // version -> dirty
+}
+
+var (
+	fakeStoresMu sync.Mutex
+	fakeStores   = map[string]*fakeMigrationsStore{}
+)
+
+func init() {
+	sql.Register("migrationfakedriver", &fakeMigrationsDriver{})
+}
+
+// newFakeMigrationsDB returns a *sql.DB backed by a fresh, empty
+// fakeMigrationsStore keyed by dsn.
+func newFakeMigrationsDB(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+
+	fakeStoresMu.Lock()
+	fakeStores[dsn] = &fakeMigrationsStore{rows: map[int]bool{}}
+	fakeStoresMu.Unlock()
+
+	db, err := sql.Open("migrationfakedriver", dsn)
+	if err != nil {
+		t.Fatalf("opening fake migrations db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+type fakeMigrationsDriver struct{}
+
+func (d *fakeMigrationsDriver) Open(dsn string) (driver.Conn, error) {
+	fakeStoresMu.Lock()
+	store, ok := fakeStores[dsn]
+	fakeStoresMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no fake migrations store registered for dsn %v", dsn)
+	}
+	return &fakeMigrationsConn{store: store}, nil
+}
+
+var (
+	mergeVersionPattern  = regexp.MustCompile(`(?is)MERGE INTO .*SELECT (\d+) AS version, (true|false) AS dirty`)
+	selectVersionPattern = regexp.MustCompile(`(?is)SELECT version, dirty FROM .*WHERE version > 0 ORDER BY version DESC LIMIT 1`)
+	deleteVersionPattern = regexp.MustCompile(`(?is)DELETE FROM .*WHERE version = (\d+)`)
+)
+
+type fakeMigrationsConn struct {
+	store *fakeMigrationsStore
+}
+
+func (c *fakeMigrationsConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeMigrationsStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeMigrationsConn) Close() error { return nil }
+
+func (c *fakeMigrationsConn) Begin() (driver.Tx, error) { return fakeMigrationsTx{}, nil }
+
+func (c *fakeMigrationsConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if m := mergeVersionPattern.FindStringSubmatch(query); m != nil {
+		version, _ := strconv.Atoi(m[1])
+		c.store.rows[version] = strings.EqualFold(m[2], "true")
+		return driver.RowsAffected(1), nil
+	}
+
+	if m := deleteVersionPattern.FindStringSubmatch(query); m != nil {
+		version, _ := strconv.Atoi(m[1])
+		delete(c.store.rows, version)
+		return driver.RowsAffected(1), nil
+	}
+
+	// CREATE TABLE, a migration step's own up/down SQL, and anything else
+	// this test isn't asserting on: accept unconditionally.
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeMigrationsConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	c.store.mu.Lock()
+	defer c.store.mu.Unlock()
+
+	if !selectVersionPattern.MatchString(query) {
+		return &fakeMigrationsRows{}, nil
+	}
+
+	versions := make([]int, 0, len(c.store.rows))
+	for v := range c.store.rows {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	if len(versions) == 0 {
+		return &fakeMigrationsRows{}, nil
+	}
+
+	v := versions[0]
+	return &fakeMigrationsRows{
+		cols:   []string{"version", "dirty"},
+		values: [][]driver.Value{{int64(v), c.store.rows[v]}},
+	}, nil
+}
+
+type fakeMigrationsStmt struct {
+	conn  *fakeMigrationsConn
+	query string
+}
+
+func (s *fakeMigrationsStmt) Close() error  { return nil }
+func (s *fakeMigrationsStmt) NumInput() int { return -1 }
+func (s *fakeMigrationsStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.Exec(s.query, args)
+}
+func (s *fakeMigrationsStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.Query(s.query, args)
+}
+
+type fakeMigrationsTx struct{}
+
+func (fakeMigrationsTx) Commit() error   { return nil }
+func (fakeMigrationsTx) Rollback() error { return nil }
+
+// fakeMigrationsRows implements driver.Rows over an already-materialized set
+// of values.
+type fakeMigrationsRows struct {
+	cols   []string
+	values [][]driver.Value
+	next   int
+}
+
+func (r *fakeMigrationsRows) Columns() []string { return r.cols }
+func (r *fakeMigrationsRows) Close() error      { return nil }
+func (r *fakeMigrationsRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.next])
+	r.next++
+	return nil
+}
+
+// TestApplyMigrationUp_singleRowPerVersion drives two migrations through
+// applyMigrationUp against the fake store above and asserts that each
+// version ends up with exactly one row, resolved to dirty=false, instead of
+// the two rows (dirty=true then dirty=false) a plain INSERT would leave
+// behind for CurrentVersion's ORDER BY ... LIMIT 1 to pick between.
+func TestApplyMigrationUp_singleRowPerVersion(t *testing.T) {
+	const dsn = "TestApplyMigrationUp_singleRowPerVersion"
+	db := newFakeMigrationsDB(t, dsn)
+	builder := snowflake.Migration("schema_migrations")
+
+	steps := []migrationStep{
+		{version: 1, up: "CREATE TABLE widgets (id NUMBER)"},
+		{version: 2, up: "ALTER TABLE widgets ADD COLUMN name VARCHAR(255)"},
+	}
+
+	for _, step := range steps {
+		if err := applyMigrationUp(db, builder, step); err != nil {
+			t.Fatalf("applyMigrationUp(%v): %v", step.version, err)
+		}
+	}
+
+	current, dirty, err := currentMigrationVersion(db, builder)
+	if err != nil {
+		t.Fatalf("currentMigrationVersion: %v", err)
+	}
+	if current != 2 {
+		t.Errorf("current version = %v, want 2", current)
+	}
+	if dirty {
+		t.Errorf("dirty = true, want false after a clean apply")
+	}
+
+	fakeStoresMu.Lock()
+	store := fakeStores[dsn]
+	fakeStoresMu.Unlock()
+
+	if len(store.rows) != len(steps) {
+		t.Fatalf("expected exactly one row per applied version, got %v rows: %v", len(store.rows), store.rows)
+	}
+	for v, d := range store.rows {
+		if d {
+			t.Errorf("version %v left dirty=true after a clean apply", v)
+		}
+	}
+}
@@ -38,12 +38,193 @@ var tableSchema = map[string]*schema.Schema{
 		Optional:    true,
 		Description: "Specifies a comment for the table.",
 	},
+	"column": &schema.Schema{
+		Type:        schema.TypeList,
+		Required:    true,
+		MinItems:    1,
+		Description: "Definitions of a column to create in the table. Minimum one required.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Column name",
+				},
+				"type": &schema.Schema{
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Column type, e.g. VARCHAR(16), NUMBER(38,0), BOOLEAN, etc.",
+				},
+				"nullable": &schema.Schema{
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Whether this column can contain null values. Defaults to true.",
+				},
+				"default": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Literal or expression to use as the column's default value, e.g. 'foo' or CURRENT_TIMESTAMP().",
+				},
+				"previous_name": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "The column's previous name, if it is being renamed. Lets a rename be expressed deterministically instead of relying on position/type detection.",
+				},
+				"identity": &schema.Schema{
+					Type:        schema.TypeList,
+					Optional:    true,
+					ForceNew:    true,
+					MaxItems:    1,
+					Description: "Defines this column as an identity column, starting and incrementing by the given values.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"start_num": &schema.Schema{
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Default:     1,
+								ForceNew:    true,
+								Description: "The number to start incrementing at.",
+							},
+							"step_num": &schema.Schema{
+								Type:        schema.TypeInt,
+								Optional:    true,
+								Default:     1,
+								ForceNew:    true,
+								Description: "Step size to increment by.",
+							},
+						},
+					},
+				},
+				"comment": &schema.Schema{
+					Type:        schema.TypeString,
+					Optional:    true,
+					ForceNew:    true,
+					Description: "Column comment",
+				},
+			},
+		},
+	},
+	"primary_key": &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Column names to use as the table's primary key, in order.",
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	},
+	"cluster_by": &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Column names to use as the clustering key for the table.",
+		Elem:        &schema.Schema{Type: schema.TypeString},
+	},
+	"data_retention_time_in_days": &schema.Schema{
+		Type:        schema.TypeInt,
+		Optional:    true,
+		ForceNew:    true,
+		Description: "Specifies the number of days for which Time Travel actions can be performed on the table.",
+	},
+	"transient": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		ForceNew:    true,
+		Default:     false,
+		Description: "Specifies a table as transient. Transient tables do not have a Fail-safe period.",
+	},
+	"temporary": &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		ForceNew:    true,
+		Default:     false,
+		Description: "Specifies a table as temporary. Temporary tables only persist for the duration of the session in which they were created.",
+	},
+	"allow_destructive_changes": &schema.Schema{
+		Type:     schema.TypeBool,
+		Optional: true,
+		Default:  false,
+		// NOTE: this guards a column DROP COLUMN, which is unrecoverable. Ideally
+		// this would be a provider-level setting, but the provider doesn't thread
+		// any config through to resources beyond the *sql.DB in meta, so it lives
+		// here until that's in place.
+		Description: "Whether to allow column changes that drop data, such as removing a column. Renames via previous_name do not need it. Defaults to false.",
+	},
 }
 
 func tableNormalizeQuery(str string) string {
 	return strings.TrimSpace(tableSpace.ReplaceAllString(str, " "))
 }
 
+// expandStringList turns a []interface{} of strings, as returned by
+// *schema.ResourceData for a TypeList of TypeString, into a []string.
+func expandStringList(raw []interface{}) []string {
+	list := make([]string, len(raw))
+	for i, v := range raw {
+		list[i] = v.(string)
+	}
+	return list
+}
+
+// expandColumns turns the "column" list from a *schema.ResourceData into the
+// []snowflake.Column the builder operates on.
+func expandColumns(raw []interface{}) []snowflake.Column {
+	columns := make([]snowflake.Column, 0, len(raw))
+
+	for _, r := range raw {
+		c := r.(map[string]interface{})
+
+		column := snowflake.Column{
+			Name:         c["name"].(string),
+			Type:         c["type"].(string),
+			PreviousName: c["previous_name"].(string),
+			Nullable:     c["nullable"].(bool),
+			Default:      c["default"].(string),
+			Comment:      c["comment"].(string),
+		}
+
+		if identities, ok := c["identity"].([]interface{}); ok && len(identities) == 1 {
+			i := identities[0].(map[string]interface{})
+			column.Identity = &snowflake.ColumnIdentity{
+				StartNum: i["start_num"].(int),
+				StepNum:  i["step_num"].(int),
+			}
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// flattenColumns turns the columns read back from Snowflake into the
+// []interface{} shape Terraform expects for the "column" list.
+func flattenColumns(columns []snowflake.Column, identities map[string]*snowflake.ColumnIdentity) []interface{} {
+	flattened := make([]interface{}, len(columns))
+
+	for i, c := range columns {
+		column := map[string]interface{}{
+			"name":     c.Name,
+			"type":     c.Type,
+			"nullable": c.Nullable,
+			"default":  c.Default,
+			"comment":  c.Comment,
+		}
+
+		if identity, ok := identities[c.Name]; ok {
+			column["identity"] = []interface{}{
+				map[string]interface{}{
+					"start_num": identity.StartNum,
+					"step_num":  identity.StepNum,
+				},
+			}
+		}
+
+		flattened[i] = column
+	}
+
+	return flattened
+}
+
 // tableDiffSuppressStatement will suppress diffs between statemens if they differ in only case or in
 // runs of whitespace (\s+ = \s). This is needed because the snowflake api does not faithfully
 // round-trip queries so we cannot do a simple character-wise comparison to detect changes.
@@ -83,6 +264,8 @@ func CreateTable(data *schema.ResourceData, meta interface{}) error {
 
 	builder := snowflake.Table(name).WithDB(database).WithSchema(schema)
 
+	builder.WithColumns(expandColumns(data.Get("column").([]interface{})))
+
 	if v, ok := data.GetOk("comment"); ok {
 		builder.WithComment(v.(string))
 	}
@@ -91,6 +274,26 @@ func CreateTable(data *schema.ResourceData, meta interface{}) error {
 		builder.WithSchema(v.(string))
 	}
 
+	if v, ok := data.GetOk("primary_key"); ok {
+		builder.WithPrimaryKey(expandStringList(v.([]interface{})))
+	}
+
+	if v, ok := data.GetOk("cluster_by"); ok {
+		builder.WithClusterBy(expandStringList(v.([]interface{})))
+	}
+
+	if v, ok := data.GetOk("data_retention_time_in_days"); ok {
+		builder.WithDataRetentionTimeInDays(v.(int))
+	}
+
+	if data.Get("transient").(bool) {
+		builder.WithTransient()
+	}
+
+	if data.Get("temporary").(bool) {
+		builder.WithTemporary()
+	}
+
 	q := builder.Create()
 	log.Print("[DEBUG] xxx ", q)
 	err := snowflake.Exec(db, q)
@@ -128,7 +331,31 @@ func ReadTable(data *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	return data.Set("database", v.DatabaseName.String)
+	if err = data.Set("database", v.DatabaseName.String); err != nil {
+		return err
+	}
+
+	descRows, err := snowflake.Query(db, snowflake.Table(table).WithDB(dbName).WithSchema(schema).Describe())
+	if err != nil {
+		return errors.Wrapf(err, "error describing columns for table %v", data.Id())
+	}
+
+	columns, err := snowflake.ScanColumns(descRows)
+	if err != nil {
+		return errors.Wrapf(err, "error reading columns for table %v", data.Id())
+	}
+
+	identRows, err := snowflake.Query(db, snowflake.Table(table).WithDB(dbName).WithSchema(schema).ShowColumnIdentity())
+	if err != nil {
+		return errors.Wrapf(err, "error reading column identities for table %v", data.Id())
+	}
+
+	identities, err := snowflake.ScanColumnIdentities(identRows)
+	if err != nil {
+		return errors.Wrapf(err, "error reading column identities for table %v", data.Id())
+	}
+
+	return data.Set("column", flattenColumns(columns, identities))
 }
 
 // UpdateTable implements schema.UpdateFunc
@@ -177,6 +404,26 @@ func UpdateTable(data *schema.ResourceData, meta interface{}) error {
 		data.SetPartial("comment")
 	}
 
+	if data.HasChange("column") {
+		old, new := data.GetChange("column")
+		oldColumns := expandColumns(old.([]interface{}))
+		newColumns := expandColumns(new.([]interface{}))
+		allowDestructive := data.Get("allow_destructive_changes").(bool)
+
+		stmts, err := builder.Migrate(oldColumns, newColumns, allowDestructive)
+		if err != nil {
+			return errors.Wrapf(err, "error planning column changes for table %v", data.Id())
+		}
+
+		for _, q := range stmts {
+			if err := snowflake.Exec(db, q); err != nil {
+				return errors.Wrapf(err, "error applying column change %q for table %v", q, data.Id())
+			}
+		}
+
+		data.SetPartial("column")
+	}
+
 	return ReadTable(data, meta)
 }
 
@@ -0,0 +1,118 @@
+package resources
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	_ "github.com/snowflakedb/gosnowflake"
+)
+
+// testAccProvider is a minimal *schema.Provider wrapping just the
+// snowflake_table resource, since this tree has no top-level provider.go to
+// import. It connects the same way cmd/tfsnow-reverse does: account/user/
+// password from the standard SNOWFLAKE_* environment variables.
+var testAccProvider *schema.Provider
+var testAccProviders map[string]terraform.ResourceProvider
+
+func init() {
+	testAccProvider = &schema.Provider{
+		ResourcesMap: map[string]*schema.Resource{
+			"snowflake_table": Table(),
+		},
+		ConfigureFunc: func(*schema.ResourceData) (interface{}, error) {
+			return sql.Open("snowflake", fmt.Sprintf(
+				"%v:%v@%v",
+				os.Getenv("SNOWFLAKE_USER"),
+				os.Getenv("SNOWFLAKE_PASSWORD"),
+				os.Getenv("SNOWFLAKE_ACCOUNT"),
+			))
+		},
+	}
+	testAccProviders = map[string]terraform.ResourceProvider{
+		"snowflake": testAccProvider,
+	}
+}
+
+// testAccPreCheck skips acceptance tests unless credentials for a real
+// account are available; it's the SDK's own TF_ACC check that gates whether
+// these run at all.
+func testAccPreCheck(t *testing.T) {
+	for _, v := range []string{"SNOWFLAKE_ACCOUNT", "SNOWFLAKE_USER", "SNOWFLAKE_PASSWORD", "SNOWFLAKE_TEST_DATABASE"} {
+		if os.Getenv(v) == "" {
+			t.Skipf("%v must be set for acceptance tests", v)
+		}
+	}
+}
+
+func TestAccTable_createRefreshImport(t *testing.T) {
+	name := acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+	database := os.Getenv("SNOWFLAKE_TEST_DATABASE")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testAccCheckTableDestroy(database, name),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccTableConfig(database, name),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("snowflake_table.test", "name", name),
+					resource.TestCheckResourceAttr("snowflake_table.test", "column.#", "2"),
+					resource.TestCheckResourceAttr("snowflake_table.test", "column.0.name", "id"),
+					resource.TestCheckResourceAttr("snowflake_table.test", "column.1.name", "email"),
+				),
+			},
+			{
+				// Refresh: re-plan against the same config should be a no-op.
+				Config:   testAccTableConfig(database, name),
+				PlanOnly: true,
+			},
+			{
+				ResourceName:      "snowflake_table.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccTableConfig(database, name string) string {
+	return fmt.Sprintf(`
+resource "snowflake_table" "test" {
+  database = %[1]q
+  schema   = "PUBLIC"
+  name     = %[2]q
+
+  column {
+    name     = "id"
+    type     = "NUMBER"
+    nullable = false
+  }
+
+  column {
+    name = "email"
+    type = "VARCHAR(255)"
+  }
+}
+`, database, name)
+}
+
+func testAccCheckTableDestroy(database, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		db := testAccProvider.Meta().(*sql.DB)
+
+		row := db.QueryRow(fmt.Sprintf(`SHOW TABLES LIKE '%v' IN DATABASE "%v"`, name, database))
+		var dummy string
+		if err := row.Scan(&dummy); err != sql.ErrNoRows {
+			return fmt.Errorf("table %v still exists in database %v", name, database)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package snowflake
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Dialect abstracts the SQL-generation details that can vary between
+// Snowflake configurations (quoting policy today; future builders may need
+// more) so that TableBuilder and friends can be exercised without a live
+// account, by swapping in a MockDialect.
+type Dialect interface {
+	// QuoteIdent quotes an identifier (table, column, etc. name) as needed.
+	QuoteIdent(name string) string
+	// QuoteString quotes and escapes a string literal.
+	QuoteString(value string) string
+	// FormatIdentity renders an IDENTITY(start, step) clause.
+	FormatIdentity(i *ColumnIdentity) string
+	// SupportsClusterBy reports whether this dialect accepts a CLUSTER BY clause.
+	SupportsClusterBy() bool
+	// RenderColumnDef renders a full column definition, as used in CREATE
+	// TABLE and ALTER TABLE ... ADD COLUMN statements.
+	RenderColumnDef(c Column) string
+}
+
+// QuotePolicy controls how SnowflakeDialect decides whether an identifier
+// needs quoting.
+type QuotePolicy int
+
+const (
+	// QuotePolicyAlways always double-quotes identifiers. This is the safe
+	// default: it round-trips any name, including ones that are
+	// case-sensitive or collide with a reserved word.
+	QuotePolicyAlways QuotePolicy = iota
+	// QuotePolicyReserved only quotes identifiers that aren't a plain
+	// upper-case, unquoted-safe name, or that collide with a reserved word.
+	// Closer to what a human-written migration would produce, which is
+	// useful when asserting exact generated SQL in tests.
+	QuotePolicyReserved
+)
+
+// unquotedIdentPattern matches identifiers that Snowflake would accept
+// unquoted: they fold to this form anyway, so quoting them is a no-op other
+// than readability.
+var unquotedIdentPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// reservedWords is a small, non-exhaustive set of keywords that always need
+// quoting under QuotePolicyReserved, since they can't be used as bare
+// identifiers.
+var reservedWords = map[string]bool{
+	"TABLE": true, "COLUMN": true, "ORDER": true, "GROUP": true,
+	"SELECT": true, "WHERE": true, "FROM": true, "PRIMARY": true,
+}
+
+// SnowflakeDialect is the Dialect used against a real Snowflake account.
+type SnowflakeDialect struct {
+	QuotePolicy QuotePolicy
+}
+
+// NewSnowflakeDialect returns the SnowflakeDialect used against a real
+// account: QuotePolicyAlways, which round-trips any identifier regardless of
+// case-sensitivity or reserved-word collisions. Tests that want to assert on
+// QuotePolicyReserved's less noisy output should construct a
+// &SnowflakeDialect{QuotePolicy: QuotePolicyReserved} directly rather than
+// going through this constructor.
+func NewSnowflakeDialect() *SnowflakeDialect {
+	return &SnowflakeDialect{QuotePolicy: QuotePolicyAlways}
+}
+
+// QuoteIdent implements Dialect.
+func (d *SnowflakeDialect) QuoteIdent(name string) string {
+	if d.QuotePolicy == QuotePolicyReserved && unquotedIdentPattern.MatchString(name) && !reservedWords[name] {
+		return name
+	}
+	return fmt.Sprintf(`"%v"`, name)
+}
+
+// QuoteString implements Dialect.
+func (d *SnowflakeDialect) QuoteString(value string) string {
+	return fmt.Sprintf(`'%v'`, strings.ReplaceAll(value, `'`, `''`))
+}
+
+// FormatIdentity implements Dialect.
+func (d *SnowflakeDialect) FormatIdentity(i *ColumnIdentity) string {
+	return fmt.Sprintf("IDENTITY(%v, %v)", i.StartNum, i.StepNum)
+}
+
+// SupportsClusterBy implements Dialect.
+func (d *SnowflakeDialect) SupportsClusterBy() bool {
+	return true
+}
+
+// RenderColumnDef implements Dialect.
+func (d *SnowflakeDialect) RenderColumnDef(c Column) string {
+	var b strings.Builder
+
+	b.WriteString(fmt.Sprintf("%v %v", d.QuoteIdent(c.Name), c.Type))
+
+	if c.Identity != nil {
+		b.WriteString(" " + d.FormatIdentity(c.Identity))
+	}
+
+	if c.Default != "" {
+		b.WriteString(fmt.Sprintf(" DEFAULT %v", c.Default))
+	}
+
+	if !c.Nullable {
+		b.WriteString(" NOT NULL")
+	}
+
+	if c.Comment != "" {
+		b.WriteString(" COMMENT " + d.QuoteString(c.Comment))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package snowflake
+
+import "fmt"
+
+// MockDialect is a Dialect that renders predictable, easy-to-assert-on SQL
+// fragments instead of Snowflake's actual quoting, so builder output can be
+// checked with go-sqlmock and friends without a live account.
+type MockDialect struct {
+	// ClusterBySupported lets tests exercise the SupportsClusterBy() == false path.
+	ClusterBySupported bool
+}
+
+// NewMockDialect returns a MockDialect with CLUSTER BY support enabled.
+func NewMockDialect() *MockDialect {
+	return &MockDialect{ClusterBySupported: true}
+}
+
+// QuoteIdent implements Dialect by bracketing the identifier instead of
+// double-quoting it, so quoted and unquoted names are visually distinct in
+// test assertions.
+func (d *MockDialect) QuoteIdent(name string) string {
+	return fmt.Sprintf("[%v]", name)
+}
+
+// QuoteString implements Dialect.
+func (d *MockDialect) QuoteString(value string) string {
+	return fmt.Sprintf("<%v>", value)
+}
+
+// FormatIdentity implements Dialect.
+func (d *MockDialect) FormatIdentity(i *ColumnIdentity) string {
+	return fmt.Sprintf("IDENTITY(%v, %v)", i.StartNum, i.StepNum)
+}
+
+// SupportsClusterBy implements Dialect.
+func (d *MockDialect) SupportsClusterBy() bool {
+	return d.ClusterBySupported
+}
+
+// RenderColumnDef implements Dialect.
+func (d *MockDialect) RenderColumnDef(c Column) string {
+	def := fmt.Sprintf("%v %v", d.QuoteIdent(c.Name), c.Type)
+
+	if c.Identity != nil {
+		def += " " + d.FormatIdentity(c.Identity)
+	}
+
+	if c.Default != "" {
+		def += fmt.Sprintf(" DEFAULT %v", c.Default)
+	}
+
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+
+	if c.Comment != "" {
+		def += " COMMENT " + d.QuoteString(c.Comment)
+	}
+
+	return def
+}
@@ -0,0 +1,26 @@
+package snowflake
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Exec runs a DDL/DML statement against db.
+func Exec(db *sql.DB, query string) error {
+	sdb := sqlx.NewDb(db, "snowflake")
+	_, err := sdb.Exec(query)
+	return err
+}
+
+// QueryRow runs query against db and returns a single row for scanning.
+func QueryRow(db *sql.DB, query string) *sqlx.Row {
+	sdb := sqlx.NewDb(db, "snowflake")
+	return sdb.QueryRowx(query)
+}
+
+// Query runs query against db and returns the resulting rows for scanning.
+func Query(db *sql.DB, query string) (*sqlx.Rows, error) {
+	sdb := sqlx.NewDb(db, "snowflake")
+	return sdb.Queryx(query)
+}
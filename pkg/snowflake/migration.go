@@ -0,0 +1,159 @@
+package snowflake
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MigrationBuilder abstracts the creation of SQL queries for managing a
+// schema_migrations-style table, modeled after the golang-migrate database
+// drivers: a single table tracks the current version and a "dirty" bit that
+// is set while a migration is in flight, so a crash mid-migration is visible
+// on the next run instead of silently leaving the schema half-migrated.
+type MigrationBuilder struct {
+	table   string
+	db      string
+	schema  string
+	dialect Dialect
+}
+
+// Migration returns a pointer to a Builder that abstracts the DDL/DML
+// operations for a migrations table.
+func Migration(table string) *MigrationBuilder {
+	return &MigrationBuilder{
+		table:   table,
+		dialect: NewSnowflakeDialect(),
+	}
+}
+
+// WithDB adds the name of the database to the MigrationBuilder
+func (mb *MigrationBuilder) WithDB(db string) *MigrationBuilder {
+	mb.db = db
+	return mb
+}
+
+// WithSchema adds the name of the schema to the MigrationBuilder
+func (mb *MigrationBuilder) WithSchema(s string) *MigrationBuilder {
+	mb.schema = s
+	return mb
+}
+
+// WithDialect overrides the Dialect used to render identifiers, e.g. to a
+// MockDialect in tests. Defaults to a NewSnowflakeDialect().
+func (mb *MigrationBuilder) WithDialect(d Dialect) *MigrationBuilder {
+	mb.dialect = d
+	return mb
+}
+
+// QualifiedName prepends the db and schema if set and quotes everything per
+// the builder's Dialect.
+func (mb *MigrationBuilder) QualifiedName() string {
+	var n strings.Builder
+
+	if mb.db != "" && mb.schema != "" {
+		n.WriteString(fmt.Sprintf(`%v.%v.`, mb.dialect.QuoteIdent(mb.db), mb.dialect.QuoteIdent(mb.schema)))
+	}
+
+	if mb.db != "" && mb.schema == "" {
+		n.WriteString(fmt.Sprintf(`%v..`, mb.dialect.QuoteIdent(mb.db)))
+	}
+
+	if mb.db == "" && mb.schema != "" {
+		n.WriteString(fmt.Sprintf(`%v.`, mb.dialect.QuoteIdent(mb.schema)))
+	}
+
+	n.WriteString(mb.dialect.QuoteIdent(mb.table))
+
+	return n.String()
+}
+
+// Create returns the SQL query that will create the migrations table, if it
+// does not already exist.
+func (mb *MigrationBuilder) Create() string {
+	return fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %v (version NUMBER NOT NULL, dirty BOOLEAN NOT NULL, applied_at TIMESTAMP_NTZ NOT NULL)`,
+		mb.QualifiedName(),
+	)
+}
+
+// Show returns the SQL query that will show the row representing the
+// migrations table itself.
+func (mb *MigrationBuilder) Show() string {
+	if mb.db == "" {
+		return fmt.Sprintf(`SHOW TABLES LIKE '%v'`, mb.table)
+	}
+	return fmt.Sprintf(`SHOW TABLES LIKE '%v' IN DATABASE %v`, mb.table, mb.dialect.QuoteIdent(mb.db))
+}
+
+// lockVersion is the reserved version number of the advisory lock row. It is
+// never a real migration version (those start at 1) so it's excluded from
+// CurrentVersion and never touched by UpsertVersion/DeleteVersion for actual
+// migrations.
+const lockVersion = 0
+
+// CurrentVersion returns the SQL query that will show the most recently
+// applied version and whether it is dirty. The advisory lock row is excluded
+// so that holding the lock doesn't look like a dirty migration.
+func (mb *MigrationBuilder) CurrentVersion() string {
+	return fmt.Sprintf(`SELECT version, dirty FROM %v WHERE version > %v ORDER BY version DESC LIMIT 1`, mb.QualifiedName(), lockVersion)
+}
+
+// EnsureLockRow returns the SQL query that inserts the advisory lock row if
+// it does not already exist. Must be run once, after Create(), before the
+// first AcquireLock.
+func (mb *MigrationBuilder) EnsureLockRow() string {
+	return fmt.Sprintf(
+		`INSERT INTO %v (version, dirty, applied_at) SELECT %v, FALSE, CURRENT_TIMESTAMP() WHERE NOT EXISTS (SELECT 1 FROM %v WHERE version = %v)`,
+		mb.QualifiedName(), lockVersion, mb.QualifiedName(), lockVersion,
+	)
+}
+
+// AcquireLock returns the SQL query that attempts to take the advisory lock
+// by flipping the lock row from unlocked to locked. Snowflake has no
+// SELECT ... FOR UPDATE, so the caller must check the driver-reported
+// RowsAffected: 0 means someone else already holds the lock.
+func (mb *MigrationBuilder) AcquireLock() string {
+	return fmt.Sprintf(`UPDATE %v SET dirty = TRUE WHERE version = %v AND dirty = FALSE`, mb.QualifiedName(), lockVersion)
+}
+
+// ReleaseLock returns the SQL query that frees the advisory lock.
+func (mb *MigrationBuilder) ReleaseLock() string {
+	return fmt.Sprintf(`UPDATE %v SET dirty = FALSE WHERE version = %v`, mb.QualifiedName(), lockVersion)
+}
+
+// UpsertVersion returns the SQL query that records a version as applied (or
+// dirty, if the migration that produced it is about to run or failed partway
+// through). A single step is recorded with this twice in a row: dirty=true
+// right before it runs, then dirty=false right after it commits. Using MERGE
+// instead of a plain INSERT keeps that to one row per version no matter how
+// many times it's called, so CurrentVersion's ORDER BY ... LIMIT 1 is never
+// choosing between two rows for the same version with no real tiebreaker.
+func (mb *MigrationBuilder) UpsertVersion(version int, dirty bool) string {
+	return fmt.Sprintf(
+		`MERGE INTO %v AS t USING (SELECT %v AS version, %v AS dirty) AS s ON t.version = s.version `+
+			`WHEN MATCHED THEN UPDATE SET dirty = s.dirty, applied_at = CURRENT_TIMESTAMP() `+
+			`WHEN NOT MATCHED THEN INSERT (version, dirty, applied_at) VALUES (s.version, s.dirty, CURRENT_TIMESTAMP())`,
+		mb.QualifiedName(), version, dirty,
+	)
+}
+
+// DeleteVersion returns the SQL query that removes a version's row, used
+// when a down migration rolls that version back.
+func (mb *MigrationBuilder) DeleteVersion(version int) string {
+	return fmt.Sprintf(`DELETE FROM %v WHERE version = %v`, mb.QualifiedName(), version)
+}
+
+type migrationVersion struct {
+	Version sql.NullInt64 `db:"version"`
+	Dirty   sql.NullBool  `db:"dirty"`
+}
+
+// ScanMigrationVersion scans the result of a CurrentVersion() query.
+func ScanMigrationVersion(row *sqlx.Row) (*migrationVersion, error) {
+	r := &migrationVersion{}
+	err := row.StructScan(r)
+	return r, err
+}
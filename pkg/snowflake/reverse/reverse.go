@@ -0,0 +1,294 @@
+// Package reverse reverse-engineers existing Snowflake tables into
+// ready-to-apply snowflake_table HCL, plus a shell script of matching
+// `terraform import` commands, so an account with existing tables can be
+// adopted without hand-writing resource blocks.
+package reverse
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Options controls which tables Generate reverse engineers and how the
+// resulting HCL is emitted.
+type Options struct {
+	Database    string
+	Schema      string // empty means every schema in Database
+	Include     *regexp.Regexp
+	Exclude     *regexp.Regexp
+	WithViews   bool
+	WithSchemas bool
+}
+
+// NewOptions builds an Options, compiling the include/exclude filters.
+func NewOptions(database, schemaName, include, exclude string, withViews, withSchemas bool) (Options, error) {
+	opts := Options{Database: database, Schema: schemaName, WithViews: withViews, WithSchemas: withSchemas}
+
+	if include != "" {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return Options{}, errors.Wrap(err, "invalid --include pattern")
+		}
+		opts.Include = re
+	}
+
+	if exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return Options{}, errors.Wrap(err, "invalid --exclude pattern")
+		}
+		opts.Exclude = re
+	}
+
+	return opts, nil
+}
+
+func (o Options) allows(name string) bool {
+	if o.Include != nil && !o.Include.MatchString(name) {
+		return false
+	}
+	if o.Exclude != nil && o.Exclude.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+// tableMeta is what Generate needs to know about a single table to render
+// both its snowflake_table block and its terraform import command.
+type tableMeta struct {
+	Schema     string
+	Name       string
+	Columns    []columnMeta
+	PrimaryKey []string
+}
+
+type columnMeta struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string
+	Comment  string
+}
+
+// Generate reverse engineers the tables visible to db under opts, and
+// returns the rendered output keyed by filename: one "<schema>.tf" of
+// snowflake_table blocks and one "<schema>_import.sh" of terraform import
+// commands per schema.
+//
+// The --include-views and --include-schemas options (Options.WithViews and
+// Options.WithSchemas) are not supported: this provider has no snowflake_view
+// or snowflake_schema resource to import into, so Generate returns an error
+// when either is set rather than silently ignoring it.
+func Generate(db *sql.DB, opts Options) (map[string]string, error) {
+	if opts.WithViews {
+		return nil, errors.New("reverse: --include-views is not supported yet: no snowflake_view resource exists in this provider")
+	}
+	if opts.WithSchemas {
+		return nil, errors.New("reverse: --include-schemas is not supported yet: no snowflake_schema resource exists in this provider")
+	}
+
+	tables, err := fetchTables(db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	bySchema := map[string][]tableMeta{}
+	for _, t := range tables {
+		bySchema[t.Schema] = append(bySchema[t.Schema], t)
+	}
+
+	files := map[string]string{}
+	for schemaName, ts := range bySchema {
+		sort.Slice(ts, func(i, j int) bool { return ts[i].Name < ts[j].Name })
+
+		var hcl, imports strings.Builder
+		imports.WriteString("#!/bin/sh\nset -eu\n\n")
+
+		for _, t := range ts {
+			hcl.WriteString(renderTable(opts.Database, t))
+			hcl.WriteString("\n")
+			fmt.Fprintf(&imports, "terraform import snowflake_table.%v '%v|%v|%v'\n", resourceName(t.Name), opts.Database, t.Schema, t.Name)
+		}
+
+		files[schemaName+".tf"] = hcl.String()
+		files[schemaName+"_import.sh"] = imports.String()
+	}
+
+	return files, nil
+}
+
+// WriteFiles writes each generated file into dir, creating it if necessary.
+func WriteFiles(dir string, files map[string]string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.Wrapf(err, "creating output directory %v", dir)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0o644); err != nil {
+			return errors.Wrapf(err, "writing %v", path)
+		}
+	}
+
+	return nil
+}
+
+func fetchTables(db *sql.DB, opts Options) ([]tableMeta, error) {
+	q := fmt.Sprintf(`SELECT TABLE_SCHEMA, TABLE_NAME FROM "%v".INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE'`, opts.Database)
+	if opts.Schema != "" {
+		q += fmt.Sprintf(` AND TABLE_SCHEMA = '%v'`, opts.Schema)
+	}
+
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing tables")
+	}
+	defer rows.Close()
+
+	var tables []tableMeta
+	for rows.Next() {
+		var schemaName, name string
+		if err := rows.Scan(&schemaName, &name); err != nil {
+			return nil, err
+		}
+		if !opts.allows(name) {
+			continue
+		}
+		tables = append(tables, tableMeta{Schema: schemaName, Name: name})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		cols, err := fetchColumns(db, opts.Database, tables[i].Schema, tables[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		tables[i].Columns = cols
+
+		pk, err := fetchPrimaryKey(db, opts.Database, tables[i].Schema, tables[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		tables[i].PrimaryKey = pk
+	}
+
+	return tables, nil
+}
+
+func fetchColumns(db *sql.DB, database, schemaName, table string) ([]columnMeta, error) {
+	q := fmt.Sprintf(
+		`SELECT COLUMN_NAME, DATA_TYPE, IS_NULLABLE, COALESCE(COLUMN_DEFAULT, ''), COALESCE(COMMENT, '')
+		 FROM "%v".INFORMATION_SCHEMA.COLUMNS
+		 WHERE TABLE_SCHEMA = '%v' AND TABLE_NAME = '%v'
+		 ORDER BY ORDINAL_POSITION`,
+		database, schemaName, table,
+	)
+
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing columns for %v.%v", schemaName, table)
+	}
+	defer rows.Close()
+
+	var cols []columnMeta
+	for rows.Next() {
+		var name, dataType, isNullable, def, comment string
+		if err := rows.Scan(&name, &dataType, &isNullable, &def, &comment); err != nil {
+			return nil, err
+		}
+		cols = append(cols, columnMeta{
+			Name:     name,
+			Type:     dataType,
+			Nullable: isNullable == "YES",
+			Default:  def,
+			Comment:  comment,
+		})
+	}
+
+	return cols, rows.Err()
+}
+
+func fetchPrimaryKey(db *sql.DB, database, schemaName, table string) ([]string, error) {
+	q := fmt.Sprintf(
+		`SELECT kcu.COLUMN_NAME
+		 FROM "%v".INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+		 JOIN "%v".INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+		   ON tc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME AND tc.TABLE_SCHEMA = kcu.TABLE_SCHEMA
+		 WHERE tc.CONSTRAINT_TYPE = 'PRIMARY KEY' AND tc.TABLE_SCHEMA = '%v' AND tc.TABLE_NAME = '%v'
+		 ORDER BY kcu.ORDINAL_POSITION`,
+		database, database, schemaName, table,
+	)
+
+	rows, err := db.Query(q)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing primary key for %v.%v", schemaName, table)
+	}
+	defer rows.Close()
+
+	var cols []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		cols = append(cols, name)
+	}
+
+	return cols, rows.Err()
+}
+
+var invalidIdentChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// resourceName derives a valid HCL resource name from a table name.
+func resourceName(table string) string {
+	return strings.ToLower(invalidIdentChars.ReplaceAllString(table, "_"))
+}
+
+func renderTable(database string, t tableMeta) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "resource \"snowflake_table\" %q {\n", resourceName(t.Name))
+	fmt.Fprintf(&b, "  name     = %q\n", t.Name)
+	fmt.Fprintf(&b, "  database = %q\n", database)
+	fmt.Fprintf(&b, "  schema   = %q\n", t.Schema)
+
+	for _, c := range t.Columns {
+		b.WriteString("\n  column {\n")
+		fmt.Fprintf(&b, "    name     = %q\n", c.Name)
+		fmt.Fprintf(&b, "    type     = %q\n", c.Type)
+		fmt.Fprintf(&b, "    nullable = %v\n", c.Nullable)
+		if c.Default != "" {
+			fmt.Fprintf(&b, "    default  = %q\n", c.Default)
+		}
+		if c.Comment != "" {
+			fmt.Fprintf(&b, "    comment  = %q\n", c.Comment)
+		}
+		b.WriteString("  }\n")
+	}
+
+	if len(t.PrimaryKey) > 0 {
+		fmt.Fprintf(&b, "\n  primary_key = %v\n", quoteList(t.PrimaryKey))
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+func quoteList(vs []string) string {
+	quoted := make([]string, len(vs))
+	for i, v := range vs {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -3,36 +3,75 @@ package snowflake
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
+// ColumnIdentity describes an IDENTITY(start, step) clause on a column.
+type ColumnIdentity struct {
+	StartNum int
+	StepNum  int
+}
+
+// Column represents a single column definition, either desired (from
+// configuration) or actual (read back from Snowflake).
+type Column struct {
+	Name string
+	Type string
+	// PreviousName, when set, identifies the column this one was renamed
+	// from. Migrate detects renames only through this field; without it, a
+	// column that disappears and another that appears are a drop and an add.
+	PreviousName string
+	Nullable     bool
+	Default      string
+	Identity     *ColumnIdentity
+	Comment      string
+}
+
 // TableBuilder abstracts the creation of SQL queries for a Snowflake Table
 type TableBuilder struct {
-	name    string
-	db      string
-	schema  string
-	comment string
+	name                    string
+	db                      string
+	schema                  string
+	comment                 string
+	columns                 []Column
+	primaryKey              []string
+	clusterBy               []string
+	dataRetentionTimeInDays *int
+	transient               bool
+	temporary               bool
+	dialect                 Dialect
 }
 
-// QualifiedName prepends the db and schema if set and escapes everything nicely
+// WithDialect overrides the Dialect used to render identifiers and column
+// definitions, e.g. to a MockDialect in tests. Defaults to a
+// NewSnowflakeDialect().
+func (vb *TableBuilder) WithDialect(d Dialect) *TableBuilder {
+	vb.dialect = d
+	return vb
+}
+
+// QualifiedName prepends the db and schema if set and quotes everything per
+// the builder's Dialect.
 func (vb *TableBuilder) QualifiedName() string {
 	var n strings.Builder
 
 	if vb.db != "" && vb.schema != "" {
-		n.WriteString(fmt.Sprintf(`"%v"."%v".`, vb.db, vb.schema))
+		n.WriteString(fmt.Sprintf(`%v.%v.`, vb.dialect.QuoteIdent(vb.db), vb.dialect.QuoteIdent(vb.schema)))
 	}
 
 	if vb.db != "" && vb.schema == "" {
-		n.WriteString(fmt.Sprintf(`"%v"..`, vb.db))
+		n.WriteString(fmt.Sprintf(`%v..`, vb.dialect.QuoteIdent(vb.db)))
 	}
 
 	if vb.db == "" && vb.schema != "" {
-		n.WriteString(fmt.Sprintf(`"%v".`, vb.schema))
+		n.WriteString(fmt.Sprintf(`%v.`, vb.dialect.QuoteIdent(vb.schema)))
 	}
 
-	n.WriteString(fmt.Sprintf(`"%v"`, vb.name))
+	n.WriteString(vb.dialect.QuoteIdent(vb.name))
 
 	return n.String()
 }
@@ -55,6 +94,42 @@ func (vb *TableBuilder) WithSchema(s string) *TableBuilder {
 	return vb
 }
 
+// WithColumns adds the column definitions to the TableBuilder
+func (vb *TableBuilder) WithColumns(c []Column) *TableBuilder {
+	vb.columns = c
+	return vb
+}
+
+// WithPrimaryKey adds a PRIMARY KEY (...) clause naming the given columns to the TableBuilder
+func (vb *TableBuilder) WithPrimaryKey(cols []string) *TableBuilder {
+	vb.primaryKey = cols
+	return vb
+}
+
+// WithClusterBy adds a CLUSTER BY (...) clause naming the given columns to the TableBuilder
+func (vb *TableBuilder) WithClusterBy(cols []string) *TableBuilder {
+	vb.clusterBy = cols
+	return vb
+}
+
+// WithDataRetentionTimeInDays adds a DATA_RETENTION_TIME_IN_DAYS parameter to the TableBuilder
+func (vb *TableBuilder) WithDataRetentionTimeInDays(days int) *TableBuilder {
+	vb.dataRetentionTimeInDays = &days
+	return vb
+}
+
+// WithTransient marks the table as TRANSIENT on the TableBuilder
+func (vb *TableBuilder) WithTransient() *TableBuilder {
+	vb.transient = true
+	return vb
+}
+
+// WithTemporary marks the table as TEMPORARY on the TableBuilder
+func (vb *TableBuilder) WithTemporary() *TableBuilder {
+	vb.temporary = true
+	return vb
+}
+
 // Table returns a pointer to a Builder that abstracts the DDL operations for a table.
 //
 // Supported DDL operations are:
@@ -66,7 +141,8 @@ func (vb *TableBuilder) WithSchema(s string) *TableBuilder {
 //
 func Table(name string) *TableBuilder {
 	return &TableBuilder{
-		name: name,
+		name:    name,
+		dialect: NewSnowflakeDialect(),
 	}
 }
 
@@ -76,15 +152,55 @@ func (vb *TableBuilder) Create() string {
 
 	q.WriteString("CREATE OR REPLACE")
 
-	q.WriteString(fmt.Sprintf(` TABLE %v(placeholder varchar(100))`, vb.QualifiedName()))
+	if vb.transient {
+		q.WriteString(" TRANSIENT")
+	}
+
+	if vb.temporary {
+		q.WriteString(" TEMPORARY")
+	}
+
+	q.WriteString(fmt.Sprintf(` TABLE %v (%v)`, vb.QualifiedName(), vb.columnDefs()))
+
+	if len(vb.clusterBy) > 0 && vb.dialect.SupportsClusterBy() {
+		q.WriteString(fmt.Sprintf(" CLUSTER BY (%v)", strings.Join(vb.quoteIdents(vb.clusterBy), ", ")))
+	}
+
+	if vb.dataRetentionTimeInDays != nil {
+		q.WriteString(fmt.Sprintf(" DATA_RETENTION_TIME_IN_DAYS = %v", *vb.dataRetentionTimeInDays))
+	}
 
 	if vb.comment != "" {
-		q.WriteString(fmt.Sprintf(" COMMENT = '%v'", vb.comment))
+		q.WriteString(" COMMENT = " + vb.dialect.QuoteString(vb.comment))
 	}
 
 	return q.String()
 }
 
+// columnDefs renders the column list and, if set, the PRIMARY KEY clause
+// that together make up the body of a CREATE TABLE statement.
+func (vb *TableBuilder) columnDefs() string {
+	defs := make([]string, 0, len(vb.columns)+1)
+	for _, c := range vb.columns {
+		defs = append(defs, vb.dialect.RenderColumnDef(c))
+	}
+
+	if len(vb.primaryKey) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%v)", strings.Join(vb.quoteIdents(vb.primaryKey), ", ")))
+	}
+
+	return strings.Join(defs, ", ")
+}
+
+// quoteIdents quotes each identifier in vs per the builder's Dialect.
+func (vb *TableBuilder) quoteIdents(vs []string) []string {
+	out := make([]string, len(vs))
+	for i, v := range vs {
+		out[i] = vb.dialect.QuoteIdent(v)
+	}
+	return out
+}
+
 // Rename returns the SQL query that will rename the table.
 func (vb *TableBuilder) Rename(newName string) string {
 	oldName := vb.QualifiedName()
@@ -96,7 +212,7 @@ func (vb *TableBuilder) Rename(newName string) string {
 // Note that comment is the only parameter, if more are released this should be
 // abstracted as per the generic builder.
 func (vb *TableBuilder) ChangeComment(c string) string {
-	return fmt.Sprintf(`ALTER TABLE %v SET COMMENT = '%v'`, vb.QualifiedName(), c)
+	return fmt.Sprintf(`ALTER TABLE %v SET COMMENT = %v`, vb.QualifiedName(), vb.dialect.QuoteString(c))
 }
 
 // RemoveComment returns the SQL query that will remove the comment on the table.
@@ -111,7 +227,7 @@ func (vb *TableBuilder) Show() string {
 	if vb.db == "" {
 		return fmt.Sprintf(`SHOW TABLES LIKE '%v'`, vb.name)
 	}
-	return fmt.Sprintf(`SHOW TABLES LIKE '%v' IN DATABASE "%v"`, vb.name, vb.db)
+	return fmt.Sprintf(`SHOW TABLES LIKE '%v' IN DATABASE %v`, vb.name, vb.dialect.QuoteIdent(vb.db))
 }
 
 // Drop returns the SQL query that will drop the row representing this table.
@@ -119,6 +235,21 @@ func (vb *TableBuilder) Drop() string {
 	return fmt.Sprintf(`DROP TABLE %v`, vb.QualifiedName())
 }
 
+// Describe returns the SQL query that will describe the columns of this table.
+func (vb *TableBuilder) Describe() string {
+	return fmt.Sprintf(`DESCRIBE TABLE %v`, vb.QualifiedName())
+}
+
+// ShowColumnIdentity returns the SQL query that reads identity metadata for
+// every column of this table. DESCRIBE TABLE does not expose identity start/step
+// directly, so this falls back to INFORMATION_SCHEMA.COLUMNS.
+func (vb *TableBuilder) ShowColumnIdentity() string {
+	return fmt.Sprintf(
+		`SELECT COLUMN_NAME, IS_IDENTITY, IDENTITY_START, IDENTITY_INCREMENT FROM %v.INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = '%v' AND TABLE_NAME = '%v'`,
+		vb.dialect.QuoteIdent(vb.db), vb.schema, vb.name,
+	)
+}
+
 type table struct {
 	Comment      sql.NullString `db:"comment"`
 	Name         sql.NullString `db:"name"`
@@ -132,3 +263,76 @@ func ScanTable(row *sqlx.Row) (*table, error) {
 	err := row.StructScan(r)
 	return r, err
 }
+
+// describedColumn represents a single row of DESCRIBE TABLE output.
+type describedColumn struct {
+	Name       sql.NullString `db:"name"`
+	Type       sql.NullString `db:"type"`
+	Nullable   sql.NullString `db:"null?"`
+	Default    sql.NullString `db:"default"`
+	PrimaryKey sql.NullString `db:"primary key"`
+	Comment    sql.NullString `db:"comment"`
+}
+
+// ScanColumns scans the result of a Describe() query into a list of Columns.
+func ScanColumns(rows *sqlx.Rows) ([]Column, error) {
+	defer rows.Close()
+
+	var columns []Column
+	for rows.Next() {
+		r := &describedColumn{}
+		if err := rows.StructScan(r); err != nil {
+			return nil, err
+		}
+
+		columns = append(columns, Column{
+			Name:     r.Name.String,
+			Type:     r.Type.String,
+			Nullable: r.Nullable.String == "Y",
+			Default:  r.Default.String,
+			Comment:  r.Comment.String,
+		})
+	}
+
+	return columns, rows.Err()
+}
+
+// columnIdentity represents a single row read from ShowColumnIdentity().
+type columnIdentity struct {
+	ColumnName        sql.NullString `db:"COLUMN_NAME"`
+	IsIdentity        sql.NullString `db:"IS_IDENTITY"`
+	IdentityStart     sql.NullString `db:"IDENTITY_START"`
+	IdentityIncrement sql.NullString `db:"IDENTITY_INCREMENT"`
+}
+
+// ScanColumnIdentities scans the result of a ShowColumnIdentity() query into
+// a map of column name to its ColumnIdentity, omitting non-identity columns.
+func ScanColumnIdentities(rows *sqlx.Rows) (map[string]*ColumnIdentity, error) {
+	defer rows.Close()
+
+	identities := map[string]*ColumnIdentity{}
+	for rows.Next() {
+		r := &columnIdentity{}
+		if err := rows.StructScan(r); err != nil {
+			return nil, err
+		}
+
+		if r.IsIdentity.String != "YES" {
+			continue
+		}
+
+		start, err := strconv.Atoi(r.IdentityStart.String)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing identity start for column %v", r.ColumnName.String)
+		}
+
+		step, err := strconv.Atoi(r.IdentityIncrement.String)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing identity increment for column %v", r.ColumnName.String)
+		}
+
+		identities[r.ColumnName.String] = &ColumnIdentity{StartNum: start, StepNum: step}
+	}
+
+	return identities, rows.Err()
+}
@@ -0,0 +1,157 @@
+package snowflake
+
+import "fmt"
+
+// columnRename pairs a column's old state with its new state when a rename
+// has been detected between two column lists.
+type columnRename struct {
+	from Column
+	to   Column
+}
+
+// Migrate compares a table's current columns (old) to its desired columns
+// from configuration (new) and returns the ordered ALTER TABLE statements
+// that reconcile them: renames, then drops, then adds, then any remaining
+// data type/default/nullability changes on columns present in both.
+//
+// Renames are only detected via Column.PreviousName; a column that
+// disappears and another that appears are otherwise always treated as an
+// unrelated drop and add, even if they share a type. Drops are destructive
+// and are only emitted when allowDestructive is true; otherwise Migrate
+// fails closed.
+func (vb *TableBuilder) Migrate(old, new []Column, allowDestructive bool) ([]string, error) {
+	renames, dropped, added := diffColumns(old, new)
+
+	if len(dropped) > 0 && !allowDestructive {
+		names := make([]string, len(dropped))
+		for i, c := range dropped {
+			names[i] = c.Name
+		}
+		return nil, fmt.Errorf("refusing to drop columns %v: set allow_destructive_changes to permit dropping columns", names)
+	}
+
+	var stmts []string
+
+	for _, r := range renames {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v RENAME COLUMN %v TO %v`, vb.QualifiedName(), vb.dialect.QuoteIdent(r.from.Name), vb.dialect.QuoteIdent(r.to.Name)))
+		stmts = append(stmts, vb.alterColumnStatements(r.to.Name, r.from, r.to)...)
+	}
+
+	for _, c := range dropped {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v DROP COLUMN %v`, vb.QualifiedName(), vb.dialect.QuoteIdent(c.Name)))
+	}
+
+	for _, c := range added {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ADD COLUMN %v`, vb.QualifiedName(), vb.dialect.RenderColumnDef(c)))
+	}
+
+	for _, o := range old {
+		n, ok := findColumn(new, o.Name)
+		if !ok {
+			continue // handled above, as a drop or as the source of a rename
+		}
+		stmts = append(stmts, vb.alterColumnStatements(o.Name, o, n)...)
+	}
+
+	return stmts, nil
+}
+
+// diffColumns splits old and new into renames, unmatched drops, and
+// unmatched adds.
+func diffColumns(old, new []Column) (renames []columnRename, dropped, added []Column) {
+	oldByName := map[string]Column{}
+	for _, c := range old {
+		oldByName[c.Name] = c
+	}
+
+	newByName := map[string]Column{}
+	for _, c := range new {
+		newByName[c.Name] = c
+	}
+
+	for _, c := range old {
+		if _, ok := newByName[c.Name]; !ok {
+			dropped = append(dropped, c)
+		}
+	}
+
+	for _, c := range new {
+		if _, ok := oldByName[c.Name]; !ok {
+			added = append(added, c)
+		}
+	}
+
+	matchedDropped := map[string]bool{}
+	matchedAdded := map[string]bool{}
+
+	// Renames are only ever detected via an explicit previous_name: without
+	// it, there's no way to tell an intentional rename apart from an
+	// unrelated drop+add of a column that happens to share a type, and
+	// guessing would silently bypass allow_destructive_changes.
+	for _, c := range added {
+		if c.PreviousName == "" {
+			continue
+		}
+		from, ok := oldByName[c.PreviousName]
+		if !ok || matchedDropped[from.Name] {
+			continue
+		}
+		renames = append(renames, columnRename{from: from, to: c})
+		matchedDropped[from.Name] = true
+		matchedAdded[c.Name] = true
+	}
+
+	dropped = withoutMatched(dropped, matchedDropped)
+	added = withoutMatched(added, matchedAdded)
+
+	return renames, dropped, added
+}
+
+func withoutMatched(cols []Column, matched map[string]bool) []Column {
+	remaining := make([]Column, 0, len(cols))
+	for _, c := range cols {
+		if !matched[c.Name] {
+			remaining = append(remaining, c)
+		}
+	}
+	return remaining
+}
+
+func findColumn(cols []Column, name string) (Column, bool) {
+	for _, c := range cols {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// alterColumnStatements returns the ALTER TABLE statements needed to bring a
+// single column, now named currentName, from old's state to new's state:
+// data type, default, and nullability.
+func (vb *TableBuilder) alterColumnStatements(currentName string, old, new Column) []string {
+	var stmts []string
+	ident := vb.dialect.QuoteIdent(currentName)
+
+	if old.Type != new.Type {
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ALTER COLUMN %v SET DATA TYPE %v`, vb.QualifiedName(), ident, new.Type))
+	}
+
+	if old.Default != new.Default {
+		if new.Default == "" {
+			stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ALTER COLUMN %v DROP DEFAULT`, vb.QualifiedName(), ident))
+		} else {
+			stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ALTER COLUMN %v SET DEFAULT %v`, vb.QualifiedName(), ident, new.Default))
+		}
+	}
+
+	if old.Nullable != new.Nullable {
+		if new.Nullable {
+			stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ALTER COLUMN %v DROP NOT NULL`, vb.QualifiedName(), ident))
+		} else {
+			stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %v ALTER COLUMN %v SET NOT NULL`, vb.QualifiedName(), ident))
+		}
+	}
+
+	return stmts
+}
@@ -0,0 +1,149 @@
+package snowflake
+
+import (
+	"reflect"
+	"testing"
+)
+
+func migrateTestBuilder() *TableBuilder {
+	return Table("widgets").WithDB("mydb").WithSchema("myschema").WithDialect(NewMockDialect())
+}
+
+func TestMigrate(t *testing.T) {
+	cases := []struct {
+		name             string
+		old              []Column
+		new              []Column
+		allowDestructive bool
+		want             []string
+		wantErr          bool
+	}{
+		{
+			name: "add column",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			want: []string{
+				`ALTER TABLE [mydb].[myschema].[widgets] ADD COLUMN [email] VARCHAR(255)`,
+			},
+		},
+		{
+			name: "drop column blocked without allow_destructive_changes",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+			},
+			wantErr: true,
+		},
+		{
+			name: "drop column allowed with allow_destructive_changes",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+			},
+			allowDestructive: true,
+			want: []string{
+				`ALTER TABLE [mydb].[myschema].[widgets] DROP COLUMN [email]`,
+			},
+		},
+		{
+			name: "rename via previous_name",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "contact_email", Type: "VARCHAR(255)", Nullable: true, PreviousName: "email"},
+			},
+			want: []string{
+				`ALTER TABLE [mydb].[myschema].[widgets] RENAME COLUMN [email] TO [contact_email]`,
+			},
+		},
+		{
+			name: "ambiguous same-type drop and add is not auto-renamed",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "referral_code", Type: "VARCHAR(255)", Nullable: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ambiguous same-type drop and add, explicitly allowed, is a drop+add not a rename",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "referral_code", Type: "VARCHAR(255)", Nullable: true},
+			},
+			allowDestructive: true,
+			want: []string{
+				`ALTER TABLE [mydb].[myschema].[widgets] DROP COLUMN [email]`,
+				`ALTER TABLE [mydb].[myschema].[widgets] ADD COLUMN [referral_code] VARCHAR(255)`,
+			},
+		},
+		{
+			name: "retype column",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "amount", Type: "NUMBER(10,0)", Nullable: false},
+			},
+			new: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "amount", Type: "NUMBER(18,2)", Nullable: false},
+			},
+			want: []string{
+				`ALTER TABLE [mydb].[myschema].[widgets] ALTER COLUMN [amount] SET DATA TYPE NUMBER(18,2)`,
+			},
+		},
+		{
+			name: "reorder columns is a no-op",
+			old: []Column{
+				{Name: "id", Type: "NUMBER", Nullable: false},
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+			},
+			new: []Column{
+				{Name: "email", Type: "VARCHAR(255)", Nullable: true},
+				{Name: "id", Type: "NUMBER", Nullable: false},
+			},
+			want: nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := migrateTestBuilder().Migrate(c.old, c.new, c.allowDestructive)
+
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("Migrate() returned no error, want one")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Migrate() returned unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Migrate() = %#v, want %#v", got, c.want)
+			}
+		})
+	}
+}